@@ -0,0 +1,172 @@
+package main
+
+// PlayerAction is the paddle's current move in its fighting-game-style
+// action state machine.
+type PlayerAction int
+
+const (
+	Idle PlayerAction = iota
+	Charging
+	Smash
+	Stunned
+)
+
+// Frame describes one tick of an action: the hitbox it presents (relative
+// to the player's own Point), whether the player's movement is locked that
+// tick, and, for ticks where the hitbox is active, the velocity it imparts
+// on whatever it hits.
+type Frame struct {
+	OffsetX, OffsetY float64
+	Width, Height    float64
+	Active           bool
+	Locked           bool
+	VX, VY           float64
+}
+
+const (
+	// stunTicks is how long a whiffed Smash leaves the player unable to
+	// act.
+	stunTicks = 20
+	// smashSpeed is the ball speed a connecting Smash imparts, well above
+	// the paddle-bounce sign flip.
+	smashSpeed = 4
+)
+
+// AllPlayerFrames is the per-tick frame data for each action. Charging
+// leaves the player free to move for its first two ticks, then locks them
+// in place for the rest of the wind-up; Smash's middle ticks carry
+// the active hitbox; Stunned's single frame repeats for stunTicks.
+var AllPlayerFrames = map[PlayerAction][]Frame{
+	Idle: nil,
+	Charging: {
+		{}, {}, {Locked: true}, {Locked: true}, {Locked: true},
+		{Locked: true}, {Locked: true}, {Locked: true},
+	},
+	Smash: {
+		{Locked: true},
+		{OffsetX: -4, OffsetY: -4, Width: 18, Height: 58, Active: true, VX: smashSpeed, VY: 0, Locked: true},
+		{OffsetX: -4, OffsetY: -4, Width: 18, Height: 58, Active: true, VX: smashSpeed, VY: 0, Locked: true},
+		{Locked: true},
+	},
+	Stunned: {
+		{Locked: true},
+	},
+}
+
+// startAction begins a, resetting its tick counter from AllPlayerFrames so
+// currentFrame walks the right table. Stunned's table is a single repeated
+// frame, so its duration is driven by stunTicks instead of table length.
+func (p *Player) startAction(a PlayerAction) {
+	p.pdl.Action = a
+	if a == Stunned {
+		p.pdl.ActionTicksLeft = stunTicks
+		return
+	}
+	p.pdl.ActionTicksLeft = len(AllPlayerFrames[a])
+}
+
+func (p *Player) toIdle() {
+	p.pdl.Action = Idle
+	p.pdl.ActionTicksLeft = 0
+}
+
+// currentFrame returns the Frame for the current tick of p.pdl.Action,
+// clamping into range so a Stunned player's single-frame table can be
+// walked for longer than its length.
+func (p *Player) currentFrame() Frame {
+	frames := AllPlayerFrames[p.pdl.Action]
+	if len(frames) == 0 {
+		return Frame{}
+	}
+	i := len(frames) - p.pdl.ActionTicksLeft
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(frames) {
+		i = len(frames) - 1
+	}
+	return frames[i]
+}
+
+func (p *Player) move(dir Direction) {
+	if dir == Up && p.pos.Y-p.pdl.Speed*2 >= 0 {
+		p.pos.Y -= p.pdl.Speed
+	}
+	if dir == Down && p.pos.Y+p.Height()+p.pdl.Speed*2 <= screenHeight {
+		p.pos.Y += p.pdl.Speed
+	}
+}
+
+// hits reports whether the player's active hitbox for this tick overlaps
+// ball. AllPlayerFrames' offsets and sizes are tuned at the base
+// resolution, so they're scaled here the same way playerWidth/ballWidth
+// are, rather than baked pre-scaled into the table.
+func (p *Player) hits(ball *Ball, f Frame) bool {
+	hx, hy := p.pos.X+f.OffsetX*scale, p.pos.Y+f.OffsetY*scale
+	w, h := f.Width*scale, f.Height*scale
+	return hx < ball.pos.X+ball.Width() && hx+w > ball.pos.X &&
+		hy < ball.pos.Y+ball.Height() && hy+h > ball.pos.Y
+}
+
+// advanceAction steps a player already mid-action through one tick: it
+// applies any unlocked movement, checks a live Smash hitbox against ball,
+// and transitions to the next action once the current one runs out. A
+// connecting Smash only flips the player back to Idle here; the velocity
+// it imparts on ball is applied by PhysicsSystem via ActiveHitbox (main.go),
+// which scales it the same way an ordinary bounce is scaled, rather than
+// duplicating that with this method's own unscaled copy of frame.VX/VY.
+func (p *Player) advanceAction(ball *Ball) {
+	frame := p.currentFrame()
+	if !frame.Locked {
+		p.move(p.Input())
+	}
+	if p.pdl.Action == Smash && frame.Active && p.hits(ball, frame) {
+		p.toIdle()
+		return
+	}
+
+	p.pdl.ActionTicksLeft--
+	if p.pdl.ActionTicksLeft > 0 {
+		return
+	}
+	switch p.pdl.Action {
+	case Charging:
+		p.startAction(Smash)
+	case Smash:
+		// The swing ran out without connecting.
+		p.startAction(Stunned)
+	case Stunned:
+		p.toIdle()
+	}
+}
+
+// Update advances the player one tick: movement and Smash input while
+// Idle, or the action state machine otherwise. While Stunned, the
+// player's Controller.Input() is never consulted.
+func (p *Player) Update(ball *Ball) {
+	if p.Controller == nil {
+		return
+	}
+	if p.pdl.Action != Idle {
+		p.advanceAction(ball)
+		return
+	}
+	if ai, ok := p.Controller.(ActionInput); ok && ai.Smash() {
+		p.startAction(Charging)
+		return
+	}
+	p.move(p.Input())
+}
+
+// ActiveHitbox implements Collider. Outside of a Smash's active frames it
+// reports the paddle's own bounds with a zero velocity, so Ball.Update
+// falls back to its usual bounce. Like hits, the Frame's offset, size, and
+// launch vector are tuned at the base resolution and scaled here.
+func (p *Player) ActiveHitbox() (x, y, w, h, vx, vy float64) {
+	if p.pdl.Action == Smash {
+		if f := p.currentFrame(); f.Active {
+			return p.pos.X + f.OffsetX*scale, p.pos.Y + f.OffsetY*scale, f.Width * scale, f.Height * scale, f.VX * scale, f.VY * scale
+		}
+	}
+	return p.pos.X, p.pos.Y, p.Width(), p.Height(), 0, 0
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// TestSmashConnectDoesNotSetBallVelocityDirectly checks that a connecting
+// Smash only flips the paddle back to Idle; the velocity it imparts on the
+// ball is PhysicsSystem's job (via ActiveHitbox), not advanceAction's, so
+// at any scale other than 1x it isn't left applying frame.VX/VY unscaled.
+func TestSmashConnectDoesNotSetBallVelocityDirectly(t *testing.T) {
+	oldScale := scale
+	scale = 2
+	defer func() { scale = oldScale }()
+
+	paddleSprite := ebiten.NewImage(int(basePlayerWidth*scale), int(basePlayerHeight*scale))
+	p := &Player{
+		pos: &Position{X: 0, Y: 0},
+		pdl: &Paddle{Speed: basePlayerSpeed * scale, Action: Smash, ActionTicksLeft: 2},
+		spr: &Sprite{Image: paddleSprite},
+	}
+
+	ballSprite := ebiten.NewImage(int(baseBallWidth*scale), int(baseBallWidth*scale))
+	ball := &Ball{
+		pos: &Position{X: 0, Y: 0},
+		vel: &Velocity{X: 1, Y: 1},
+		spr: &Sprite{Image: ballSprite},
+	}
+
+	frame := p.currentFrame()
+	if !frame.Active {
+		t.Fatalf("test setup error: ActionTicksLeft=2 should land on an Active Smash frame, got %+v", frame)
+	}
+
+	// ActiveHitbox is what PhysicsSystem actually consults; it must report
+	// the scaled launch vector.
+	if _, _, _, _, vx, vy := p.ActiveHitbox(); vx != smashSpeed*scale || vy != 0 {
+		t.Fatalf("ActiveHitbox vx,vy = %v,%v, want %v,0", vx, vy, smashSpeed*scale)
+	}
+
+	before := *ball.vel
+	p.advanceAction(ball)
+
+	if *ball.vel != before {
+		t.Fatalf("advanceAction changed ball.vel to %+v directly; that's PhysicsSystem's job via ActiveHitbox", *ball.vel)
+	}
+	if p.pdl.Action != Idle {
+		t.Fatalf("p.pdl.Action = %v, want Idle after a connecting Smash", p.pdl.Action)
+	}
+}
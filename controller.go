@@ -1,8 +1,6 @@
 package main
 
 import (
-	"math"
-
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
@@ -18,6 +16,31 @@ type Controller interface {
 	Input() Direction
 }
 
+// ActionInput is implemented by controllers that can also trigger a
+// Player's actions (Smash, ...) beyond plain movement. It's optional:
+// Player.Update type-asserts for it rather than requiring it on
+// Controller, so controllers that only move the paddle (FollowBall,
+// NetController) don't need a no-op implementation.
+//
+// Only ArrowKeys implements it today: FollowBall, MouseController,
+// TouchController, and NetController all just move the paddle. For
+// NetController in particular this isn't a stub waiting to be filled in
+// casually - its wire format (netplay.go's FrameInput) only carries a
+// Direction, so a remote player can never Smash until that format grows an
+// action bit. Tracked as a follow-up; 2-player netplay ships without it.
+type ActionInput interface {
+	Smash() bool
+}
+
+// Aim is implemented by pointer-based controllers (MouseController,
+// TouchController) that can report where the player is pointing, in
+// radians, for a future Smash action to launch the ball toward. ok is
+// false when there's nothing to aim with right now (cursor outside the
+// window, no active touch).
+type Aim interface {
+	Aim() (radians float64, ok bool)
+}
+
 type ArrowKeys struct{}
 
 func (ak *ArrowKeys) Input() Direction {
@@ -32,6 +55,10 @@ func (ak *ArrowKeys) Input() Direction {
 	return Down
 }
 
+func (ak *ArrowKeys) Smash() bool {
+	return ebiten.IsKeyPressed(ebiten.KeySpace)
+}
+
 type FollowBall struct {
 	Ball         *Ball
 	Player       *Player
@@ -39,6 +66,18 @@ type FollowBall struct {
 	count        int
 }
 
+// abs returns the absolute value of x. FollowBall used to call math.Abs
+// here, but rollback netplay re-runs Input() many times per tick while
+// resimulating, and it must produce the exact same Direction on every peer
+// each time it does. A plain comparison sidesteps any doubt about libm
+// returning different results across platforms.
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
 // Input should be called in game.Update()
 func (fb *FollowBall) Input() Direction {
 	// decisionBuffer is the number of ticks for which the FollowBall controller
@@ -51,13 +90,13 @@ func (fb *FollowBall) Input() Direction {
 		fb.count--
 		return fb.lastDecision
 	}
-	if math.Abs(ballX-x) < 0.6*screenWidth {
+	if abs(ballX-x) < 0.6*screenWidth {
 		//the ball is close enough to see
-		if ballY < y-fb.Player.Speed {
+		if ballY < y-fb.Player.pdl.Speed {
 			fb.count += decisionBuffer
 			fb.lastDecision = Up
 			return Up
-		} else if ballY > y+fb.Player.Speed {
+		} else if ballY > y+fb.Player.pdl.Speed {
 			fb.count += decisionBuffer
 			fb.lastDecision = Down
 			return Down
@@ -0,0 +1,123 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// Entity is an opaque handle into a World's component stores. It carries
+// no data itself; everything about an entity lives in the maps it indexes.
+type Entity uint32
+
+// Position is an entity's top-left point. Player and Ball hold a pointer
+// to their entity's Position directly, so this is the only copy of it.
+type Position struct {
+	X, Y float64
+}
+
+// Velocity is an entity's per-tick X/Y speed.
+type Velocity struct {
+	X, Y float64
+}
+
+// Sprite is what RenderSystem draws an entity with.
+type Sprite struct {
+	Image *ebiten.Image
+}
+
+// ControllerComp lets InputSystem/AISystem drive an entity without caring
+// whether the underlying Controller is a human, an AI, or the network.
+type ControllerComp struct {
+	Controller
+}
+
+// Paddle tags an entity as a paddle (as opposed to, say, a ball) and holds
+// the speed and Idle/Charging/Smash/Stunned state action.go's state
+// machine drives. Player holds a pointer to its entity's Paddle directly.
+type Paddle struct {
+	Speed           float64
+	Action          PlayerAction
+	ActionTicksLeft int
+}
+
+// System is one stage of the per-tick pipeline. Systems run in the order
+// they're registered with World.Register, so e.g. InputSystem always
+// finishes moving its paddle before PhysicsSystem checks it for a
+// collision.
+type System interface {
+	Update(world *World) error
+	Draw(world *World, screen *ebiten.Image)
+}
+
+// World owns every entity's components. Player and Ball are thin views
+// over the Position/Velocity/Sprite/Paddle components of their own
+// entity (buildWorld wires the same pointers into both), so there's a
+// single copy of a paddle's position or a ball's velocity, not a mirror
+// kept in sync after the fact. InputSystem/AISystem and PhysicsSystem still
+// delegate to Player.Update and Ball.Update, respectively, for the
+// action-state and collision logic
+// those already implement, rather than duplicating it as free functions
+// over the component maps; RenderSystem, which has no such logic to
+// reuse, draws straight from Positions/Sprites.
+type World struct {
+	entities []Entity
+	next     Entity
+
+	Positions   map[Entity]*Position
+	Velocities  map[Entity]*Velocity
+	Sprites     map[Entity]*Sprite
+	Colliders   map[Entity]Collider
+	Controllers map[Entity]*ControllerComp
+	Paddles     map[Entity]*Paddle
+
+	systems []System
+
+	players    map[Entity]*Player
+	ballEntity Entity
+	ball       *Ball
+}
+
+// NewWorld returns an empty World with every component store initialized.
+func NewWorld() *World {
+	return &World{
+		Positions:   make(map[Entity]*Position),
+		Velocities:  make(map[Entity]*Velocity),
+		Sprites:     make(map[Entity]*Sprite),
+		Colliders:   make(map[Entity]Collider),
+		Controllers: make(map[Entity]*ControllerComp),
+		Paddles:     make(map[Entity]*Paddle),
+		players:     make(map[Entity]*Player),
+	}
+}
+
+// NewEntity allocates a fresh Entity. Entities are handed out in
+// increasing order and World.entities records that order, so anything
+// iterating every entity (PhysicsSystem's collider list, RenderSystem's
+// draw order) sees a stable, deterministic sequence rather than Go's
+// randomized map iteration order.
+func (w *World) NewEntity() Entity {
+	e := w.next
+	w.next++
+	w.entities = append(w.entities, e)
+	return e
+}
+
+// Register appends a system to the pipeline, to run after every system
+// already registered.
+func (w *World) Register(s System) {
+	w.systems = append(w.systems, s)
+}
+
+// Update runs every registered system in registration order.
+func (w *World) Update() error {
+	for _, s := range w.systems {
+		if err := s.Update(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Draw runs every registered system's Draw in registration order.
+func (w *World) Draw(screen *ebiten.Image) {
+	for _, s := range w.systems {
+		s.Draw(w, screen)
+	}
+}
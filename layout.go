@@ -0,0 +1,75 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// BaseWidth and BaseHeight are the logical resolution every size and speed
+// constant below is tuned against. screenWidth/screenHeight track the
+// actual outside window size reported by Game.Layout, and scale is how far
+// that is from the base resolution, so the rest of the game can stay
+// written in terms of base units and get resolution independence for free.
+const (
+	BaseWidth  = 320
+	BaseHeight = 240
+
+	basePlayerWidth  = 10
+	basePlayerHeight = 50
+	basePlayerSpeed  = 2
+	baseBallWidth    = 10
+)
+
+var (
+	screenWidth  = float64(BaseWidth)
+	screenHeight = float64(BaseHeight)
+	scale        = 1.0
+)
+
+// Layout implements ebiten.Game interface. It reports back whatever size
+// ebiten actually gave the window, and recomputes scale against it so a
+// resize (or a high-DPI outside size ebiten picks on its own) changes the
+// game's sizes and speeds instead of just stretching the same pixels.
+//
+// A netplayLocked Game skips that recompute and reports the frozen
+// screenWidth/screenHeight instead, which ebiten scales to fit the window:
+// see the field's doc comment for why rescaling mid-session would desync
+// the two peers.
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	if g.netplayLocked {
+		return int(screenWidth), int(screenHeight)
+	}
+
+	oldWidth, oldHeight := screenWidth, screenHeight
+	screenWidth, screenHeight = float64(outsideWidth), float64(outsideHeight)
+
+	newScale := screenHeight / BaseHeight
+	if newScale != scale {
+		scale = newScale
+		g.rescale(oldWidth, oldHeight)
+	}
+	return outsideWidth, outsideHeight
+}
+
+// rescale re-derives player1 and player2's sizes and speeds and the ball's
+// size from the base constants at the new scale. Positions and the ball's
+// velocity are scaled by how far the window grew on each axis, so a resize
+// mid-game doesn't strand a paddle outside the new bounds.
+func (g *Game) rescale(oldWidth, oldHeight float64) {
+	sx, sy := screenWidth/oldWidth, screenHeight/oldHeight
+
+	playerSprite := ebiten.NewImage(int(basePlayerWidth*scale), int(basePlayerHeight*scale))
+	playerSprite.Fill(color.White)
+	for _, p := range []*Player{&g.player1, &g.player2} {
+		p.pos.X, p.pos.Y = p.pos.X*sx, p.pos.Y*sy
+		p.pdl.Speed = basePlayerSpeed * scale
+		p.spr.Image = playerSprite
+	}
+
+	ballSprite := ebiten.NewImage(int(baseBallWidth*scale), int(baseBallWidth*scale))
+	ballSprite.Fill(color.White)
+	g.ball.pos.X, g.ball.pos.Y = g.ball.pos.X*sx, g.ball.pos.Y*sy
+	g.ball.vel.X, g.ball.vel.Y = g.ball.vel.X*sx, g.ball.vel.Y*sy
+	g.ball.spr.Image = ballSprite
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// TestBallTrajectoryScaleInvariant checks that the ball's wall-bounce
+// trajectory has the same shape at 2x scale as at the base scale: every
+// tick's position and speed at 2x, divided back down by scale, lines up
+// with the base-scale run. A resize shouldn't change how the game plays,
+// only how big it looks.
+func TestBallTrajectoryScaleInvariant(t *testing.T) {
+	const ticks = 180
+
+	oldWidth, oldHeight, oldScale := screenWidth, screenHeight, scale
+	defer func() { screenWidth, screenHeight, scale = oldWidth, oldHeight, oldScale }()
+
+	run := func(s float64) []Point {
+		scale = s
+		screenWidth, screenHeight = BaseWidth*s, BaseHeight*s
+
+		sprite := ebiten.NewImage(int(baseBallWidth*s), int(baseBallWidth*s))
+		sprite.Fill(color.White)
+		b := Ball{
+			spr: &Sprite{Image: sprite},
+			pos: &Position{X: screenWidth/2 - baseBallWidth*s/2, Y: screenHeight/2 - baseBallWidth*s/2},
+			vel: &Velocity{X: 1 * s, Y: 1 * s},
+		}
+
+		positions := make([]Point, ticks)
+		for i := 0; i < ticks; i++ {
+			b.Update()
+			positions[i] = Point{X: b.pos.X, Y: b.pos.Y}
+		}
+		return positions
+	}
+
+	base := run(1)
+	doubled := run(2)
+
+	for i := range base {
+		gotX, gotY := doubled[i].X/2, doubled[i].Y/2
+		if gotX != base[i].X || gotY != base[i].Y {
+			t.Fatalf("tick %d: base=(%.4f,%.4f) doubled/2=(%.4f,%.4f)", i, base[i].X, base[i].Y, gotX, gotY)
+		}
+	}
+}
+
+// TestNetplayLockedGameIgnoresLayoutResize checks that a netplayLocked
+// Game's Layout leaves screenWidth/screenHeight/scale untouched. Two
+// netplay peers whose windows disagree even once would otherwise compute
+// different paddle speed, sprite size, and Smash velocity and
+// permanently desync their simulations.
+func TestNetplayLockedGameIgnoresLayoutResize(t *testing.T) {
+	oldWidth, oldHeight, oldScale := screenWidth, screenHeight, scale
+	defer func() { screenWidth, screenHeight, scale = oldWidth, oldHeight, oldScale }()
+	screenWidth, screenHeight, scale = BaseWidth, BaseHeight, 1
+
+	g := &Game{netplayLocked: true}
+	g.Layout(BaseWidth*3, BaseHeight*3)
+
+	if screenWidth != BaseWidth || screenHeight != BaseHeight || scale != 1 {
+		t.Fatalf("netplayLocked Layout changed screenWidth=%v screenHeight=%v scale=%v, want unchanged base values",
+			screenWidth, screenHeight, scale)
+	}
+}
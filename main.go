@@ -1,185 +1,104 @@
 package main
 
 import (
-	"fmt"
+	"flag"
 	"image/color"
 	"log"
-	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
-const (
-	screenWidth  = 320
-	screenHeight = 240
-)
-
-type Direction int
-
-const (
-	Up Direction = iota
-	Down
-	Nothing
-)
-
-type Controller interface {
-	Input() Direction
-}
-
-type ArrowKeys struct{}
-
-func (ak *ArrowKeys) Input() Direction {
-	upPressed := ebiten.IsKeyPressed(ebiten.KeyArrowUp)
-	downPressed := ebiten.IsKeyPressed(ebiten.KeyArrowDown)
-	if upPressed == downPressed {
-		return Nothing
-	}
-	if upPressed {
-		return Up
-	}
-	return Down
-}
-
-type FollowBall struct {
-	Ball         *Ball
-	Player       *Player
-	lastDecision Direction
-	count        int
-}
-
-// Input should be called in game.Update()
-func (fb *FollowBall) Input() Direction {
-	// decisionBuffer is the number of ticks for which the FollowBall controller
-	// must keep the last decision that it made
-	const decisionBuffer = 15
-
-	ballX, ballY := fb.Ball.Location()
-	x, y := fb.Player.Location()
-	if fb.count > 0 {
-		fb.count--
-		return fb.lastDecision
-	}
-	if math.Abs(ballX-x) < 0.6*screenWidth {
-		//the ball is close enough to see
-		if ballY < y-fb.Player.Speed {
-			fb.count += decisionBuffer
-			fb.lastDecision = Up
-			return Up
-		} else if ballY > y+fb.Player.Speed {
-			fb.count += decisionBuffer
-			fb.lastDecision = Down
-			return Down
-		}
-	}
-	return Nothing
-}
-
 type Point struct {
 	X float64
 	Y float64
 }
 
-func (p Point) Location() (x, y float64) {
-	return p.X, p.Y
-}
-
+// Player is a thin view over the Position, Sprite, and Paddle components
+// World owns for its entity: pos/spr/pdl are the same pointers stored in
+// World.Positions/Sprites/Paddles, so there's no separate Player state to
+// keep in sync with the ECS's. action.go's state machine reads and writes
+// them directly.
 type Player struct {
-	Sprite *ebiten.Image
-	// Point is the top left corner of the player
-	Point
+	pos *Position
+	spr *Sprite
+	pdl *Paddle
 	Controller
-	Speed float64
 }
 
-func (p *Player) Height() float64 {
-	return float64(p.Sprite.Bounds().Max.Y)
+func (p *Player) Location() (x, y float64) {
+	return p.pos.X, p.pos.Y
 }
 
-func (p *Player) Width() float64 {
-	return float64(p.Sprite.Bounds().Max.X)
+func (p *Player) Height() float64 {
+	return float64(p.spr.Image.Bounds().Max.Y)
 }
 
-func (p *Player) Draw(screen *ebiten.Image) {
-	options := &ebiten.DrawImageOptions{}
-	options.GeoM.Translate(p.X, p.Y)
-	screen.DrawImage(p.Sprite, options)
+func (p *Player) Width() float64 {
+	return float64(p.spr.Image.Bounds().Max.X)
 }
 
-func (p *Player) Update() {
-	if p.Controller != nil {
-		dir := p.Input()
-		if dir == Up && p.Y-p.Speed*2 >= 0 {
-			p.Y -= p.Speed
-		}
-		if dir == Down && p.Y+p.Height()+p.Speed*2 <= screenHeight {
-			p.Y += p.Speed
-		}
-	}
+// Ball is a thin view over the Position, Velocity, and Sprite components
+// World owns for its entity, the same way Player is.
+type Ball struct {
+	pos *Position
+	vel *Velocity
+	spr *Sprite
 }
 
-type Ball struct {
-	Sprite *ebiten.Image
-	Point
-	XSpeed float64
-	YSpeed float64
+func (b *Ball) Location() (x, y float64) {
+	return b.pos.X, b.pos.Y
 }
 
 func (b *Ball) Height() float64 {
-	return float64(b.Sprite.Bounds().Max.Y)
+	return float64(b.spr.Image.Bounds().Max.Y)
 }
 
 func (b *Ball) Width() float64 {
-	return float64(b.Sprite.Bounds().Max.X)
+	return float64(b.spr.Image.Bounds().Max.X)
 }
 
+// Collider is anything Ball.Update can bounce off. ActiveHitbox reports
+// the collider's current hitbox and the velocity that should be imparted
+// on anything that hits it. Most colliders (an idle paddle) return a zero
+// vx, vy, which tells Ball.Update to fall back to mirroring the ball's own
+// speed rather than overriding it; a Player mid-Smash returns its attack's
+// offset hitbox and launch vector instead.
 type Collider interface {
-	Height() float64
-	Width() float64
-	// Location returns the (x, y) coordinates of the top left point of the
-	// collision box
-	Location() (float64, float64)
+	ActiveHitbox() (x, y, w, h, vx, vy float64)
 }
 
 func (b *Ball) Update(colliders ...Collider) {
 	for _, c := range colliders {
-		if ptr, ok := c.(*Ball); ok {
-			if ptr == b {
-				panic("ball.Update: ball cannot collide with itself")
-			}
-		}
-
-		cX, cY := c.Location()
-		inXArea := b.X+b.Width() >= cX && b.X <= cX+c.Width()
-		inYArea := b.Y >= cY && b.Y+b.Height() <= cY+c.Height()
+		cX, cY, cW, cH, vx, vy := c.ActiveHitbox()
+		inXArea := b.pos.X+b.Width() >= cX && b.pos.X <= cX+cW
+		inYArea := b.pos.Y >= cY && b.pos.Y+b.Height() <= cY+cH
 		inPaddle := inXArea && inYArea
-		nextX := b.X + b.XSpeed
-		nextY := b.Y + b.YSpeed
-		inNextX := nextX+b.Width() >= cX && nextX <= cX+c.Width()
-		inNextY := nextY >= cY && nextY+b.Height() <= cY+c.Height()
+		nextX := b.pos.X + b.vel.X
+		nextY := b.pos.Y + b.vel.Y
+		inNextX := nextX+b.Width() >= cX && nextX <= cX+cW
+		inNextY := nextY >= cY && nextY+b.Height() <= cY+cH
 		inPaddleNextTick := inNextX && inNextY
 		if !inPaddle && inPaddleNextTick {
-			b.XSpeed *= -1
+			if vx != 0 || vy != 0 {
+				b.vel.X, b.vel.Y = vx, vy
+			} else {
+				b.vel.X *= -1
+			}
 			// No need to check the rest once it has bounced
 			break
 		}
 	}
 
 	// Temporarily keeping the ball in the screen
-	if b.X <= 0 || b.X+b.Width() >= screenWidth {
+	if b.pos.X <= 0 || b.pos.X+b.Width() >= screenWidth {
 		//game over
-		b.XSpeed = -b.XSpeed
+		b.vel.X = -b.vel.X
 	}
-	if b.Y <= 0 || b.Y+b.Height() >= screenHeight {
-		b.YSpeed = -b.YSpeed
+	if b.pos.Y <= 0 || b.pos.Y+b.Height() >= screenHeight {
+		b.vel.Y = -b.vel.Y
 	}
-	b.X += b.XSpeed
-	b.Y += b.YSpeed
-}
-
-func (b *Ball) Draw(screen *ebiten.Image) {
-	options := &ebiten.DrawImageOptions{}
-	options.GeoM.Translate(b.X, b.Y)
-	screen.DrawImage(b.Sprite, options)
+	b.pos.X += b.vel.X
+	b.pos.Y += b.vel.Y
 }
 
 // Game implements ebiten.Game interface.
@@ -187,74 +106,231 @@ type Game struct {
 	player1 Player
 	player2 Player
 	ball    Ball
+	// world runs the same simulation as a pipeline of Systems
+	// (ecs.go/systems.go) instead of Update calling player1/player2/ball
+	// directly. It's built once in setup.
+	world *World
+
+	// Score is tracked here, not in World, since nothing that currently
+	// scores a point exists yet; it's wired into the render dirty-check
+	// now so it doesn't need touching again once scoring lands.
+	Score [2]int
+
+	render renderState
+
+	// netplayLocked freezes Layout's rescaling once a netplay session has
+	// started (NewRollbackGame sets it). screenWidth/screenHeight/scale are
+	// package globals every peer's simulation reads directly for paddle
+	// speed, sprite size, and Smash velocity; if the two peers' windows so
+	// much as resize differently after the Handshake both sides started
+	// from, their physics diverges permanently. Freezing them keeps both
+	// sides identical for the life of the session; the window still scales
+	// visually, it just stops feeding back into gameplay.
+	netplayLocked bool
 }
 
 // Update proceeds the game state.
 // Update is called every tick (1/60 [s] by default).
 func (g *Game) Update() error {
-	// Write your game's logical update.
-	g.player1.Update()
-	g.player2.Update()
-	g.ball.Update(&g.player1, &g.player2)
-	if ebiten.CurrentTPS() < 55 {
-		fmt.Println("TPS:", ebiten.CurrentTPS())
-		fmt.Println("FPS:", ebiten.CurrentFPS())
+	if err := g.world.Update(); err != nil {
+		return err
 	}
+	g.toggleDebugOverlay()
+	g.markDirty()
 	return nil
 }
 
+// PlayerState is the part of Player that Snapshot/Restore need to rewind:
+// everything that changes tick to tick, minus the Sprite and Controller,
+// which stay constant for the life of a game.
+type PlayerState struct {
+	Point
+	Speed           float64
+	Action          PlayerAction
+	ActionTicksLeft int
+}
+
+// BallState is the rewindable part of Ball.
+type BallState struct {
+	Point
+	XSpeed float64
+	YSpeed float64
+}
+
+// followBallState is the rewindable part of FollowBall's decision buffer.
+// It's only populated when the matching player is actually driven by a
+// FollowBall, so a human-vs-human snapshot carries no AI state at all.
+type followBallState struct {
+	lastDecision Direction
+	count        int
+}
+
+// Snapshot is a point-in-time copy of everything Game.Update mutates. It
+// holds no pointers into the live Game, so restoring an older Snapshot
+// never clobbers a newer one still held elsewhere (e.g. by a rollback
+// history buffer).
+type Snapshot struct {
+	Player1    PlayerState
+	Player2    PlayerState
+	Ball       BallState
+	FollowBall *followBallState
+}
+
+// Snapshot captures the current simulation state. Netplay rollback keeps
+// one of these per unconfirmed frame so it can restore to any of them and
+// resimulate forward with corrected peer input.
+func (g *Game) Snapshot() Snapshot {
+	s := Snapshot{
+		Player1: PlayerState{
+			Point: Point{X: g.player1.pos.X, Y: g.player1.pos.Y}, Speed: g.player1.pdl.Speed,
+			Action: g.player1.pdl.Action, ActionTicksLeft: g.player1.pdl.ActionTicksLeft,
+		},
+		Player2: PlayerState{
+			Point: Point{X: g.player2.pos.X, Y: g.player2.pos.Y}, Speed: g.player2.pdl.Speed,
+			Action: g.player2.pdl.Action, ActionTicksLeft: g.player2.pdl.ActionTicksLeft,
+		},
+		Ball: BallState{Point: Point{X: g.ball.pos.X, Y: g.ball.pos.Y}, XSpeed: g.ball.vel.X, YSpeed: g.ball.vel.Y},
+	}
+	if fb, ok := g.player2.Controller.(*FollowBall); ok {
+		s.FollowBall = &followBallState{lastDecision: fb.lastDecision, count: fb.count}
+	}
+	return s
+}
+
+// Restore rewinds Game to a previously captured Snapshot.
+func (g *Game) Restore(s Snapshot) {
+	g.player1.pos.X, g.player1.pos.Y, g.player1.pdl.Speed = s.Player1.X, s.Player1.Y, s.Player1.Speed
+	g.player1.pdl.Action, g.player1.pdl.ActionTicksLeft = s.Player1.Action, s.Player1.ActionTicksLeft
+	g.player2.pos.X, g.player2.pos.Y, g.player2.pdl.Speed = s.Player2.X, s.Player2.Y, s.Player2.Speed
+	g.player2.pdl.Action, g.player2.pdl.ActionTicksLeft = s.Player2.Action, s.Player2.ActionTicksLeft
+	g.ball.pos.X, g.ball.pos.Y = s.Ball.X, s.Ball.Y
+	g.ball.vel.X, g.ball.vel.Y = s.Ball.XSpeed, s.Ball.YSpeed
+	if s.FollowBall != nil {
+		if fb, ok := g.player2.Controller.(*FollowBall); ok {
+			fb.lastDecision, fb.count = s.FollowBall.lastDecision, s.FollowBall.count
+		}
+	}
+}
+
 // Draw draws the game screen.
 // Draw is called every frame (typically 1/60[s] for 60Hz display).
 func (g *Game) Draw(screen *ebiten.Image) {
-	// Write your game's rendering.
-	g.player1.Draw(screen)
-	g.player2.Draw(screen)
-	g.ball.Draw(screen)
+	g.drawDirty(screen)
+	g.world.Draw(screen)
+	g.drawDebugOverlay(screen)
+}
+
+// newInputController builds player1's Controller for the -input flag:
+// "keys" (the default) for ArrowKeys, or "mouse"/"touch" for the
+// corresponding pointer controller. Any other value falls back to keys.
+func newInputController(input string, player *Player) Controller {
+	switch input {
+	case "mouse":
+		return &MouseController{Player: player}
+	case "touch":
+		return &TouchController{Player: player}
+	default:
+		return &ArrowKeys{}
+	}
 }
 
-// Layout takes the outside size (e.g., the window size) and returns the (logical) screen size.
-// If you don't have to adjust the screen size with the outside size, just return a fixed size.
-func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return screenWidth, screenHeight
+// setup builds game's World: the Position/Sprite/Paddle/Velocity
+// components for player1, player2, and the ball, then wires player1,
+// player2, and ball up as thin views over them. peer, if non-nil, drives
+// player2 over the network for a RollbackGame; otherwise player2 falls
+// back to the FollowBall AI.
+func setup(game *Game, input string, peer *NetController) {
+	game.world = buildWorld(game, input, peer)
 }
 
-func setup(game *Game) {
-	const playerWidth = 10
-	const playerHeight = 50
-	const playerSpeed = 2
-	playerSprite := ebiten.NewImage(playerWidth, playerHeight)
+// buildWorld creates the entities and components backing game's player1,
+// player2, and ball, wires game.player1/player2/ball up as views over
+// them, and registers the system pipeline that drives them each tick.
+func buildWorld(game *Game, input string, peer *NetController) *World {
+	w := NewWorld()
+
+	p1 := w.NewEntity()
+	p2 := w.NewEntity()
+	ball := w.NewEntity()
+	w.ballEntity = ball
+
+	playerSprite := ebiten.NewImage(int(basePlayerWidth*scale), int(basePlayerHeight*scale))
 	playerSprite.Fill(color.White)
-	game.player1 = Player{
-		Sprite: playerSprite, Controller: &ArrowKeys{}, Speed: playerSpeed,
-	}
-	game.player2 = Player{
-		Sprite: playerSprite,
-		Point:  Point{screenWidth - playerWidth, screenHeight - playerHeight},
-		Speed:  playerSpeed,
-	}
+	w.Positions[p1] = &Position{}
+	w.Sprites[p1] = &Sprite{Image: playerSprite}
+	w.Paddles[p1] = &Paddle{Speed: basePlayerSpeed * scale}
 
-	const ballWidth = 10
-	ballSprite := ebiten.NewImage(ballWidth, ballWidth)
+	w.Positions[p2] = &Position{X: screenWidth - basePlayerWidth*scale, Y: screenHeight - basePlayerHeight*scale}
+	w.Sprites[p2] = &Sprite{Image: playerSprite}
+	w.Paddles[p2] = &Paddle{Speed: basePlayerSpeed * scale}
+
+	ballSprite := ebiten.NewImage(int(baseBallWidth*scale), int(baseBallWidth*scale))
 	ballSprite.Fill(color.White)
-	game.ball = Ball{
-		Sprite: ballSprite,
-		Point:  Point{X: screenWidth/2 - ballWidth/2, Y: screenHeight/2 - ballWidth/2},
-		XSpeed: 1,
-		YSpeed: 1,
+	w.Positions[ball] = &Position{X: screenWidth/2 - baseBallWidth*scale/2, Y: screenHeight/2 - baseBallWidth*scale/2}
+	w.Sprites[ball] = &Sprite{Image: ballSprite}
+	w.Velocities[ball] = &Velocity{X: scale, Y: scale}
+
+	game.player1 = Player{pos: w.Positions[p1], spr: w.Sprites[p1], pdl: w.Paddles[p1]}
+	game.player1.Controller = newInputController(input, &game.player1)
+
+	game.player2 = Player{pos: w.Positions[p2], spr: w.Sprites[p2], pdl: w.Paddles[p2]}
+	if peer != nil {
+		game.player2.Controller = peer
+	} else {
+		game.player2.Controller = &FollowBall{Ball: &game.ball, Player: &game.player2}
 	}
 
-	fb := &FollowBall{Ball: &game.ball, Player: &game.player2}
-	game.player2.Controller = fb
+	game.ball = Ball{pos: w.Positions[ball], vel: w.Velocities[ball], spr: w.Sprites[ball]}
+	w.ball = &game.ball
+
+	w.players[p1], w.players[p2] = &game.player1, &game.player2
+	w.Colliders[p1], w.Colliders[p2] = &game.player1, &game.player2
+	w.Controllers[p1] = &ControllerComp{Controller: game.player1.Controller}
+	w.Controllers[p2] = &ControllerComp{Controller: game.player2.Controller}
+
+	w.Register(InputSystem{})
+	w.Register(AISystem{})
+	w.Register(PhysicsSystem{})
+	w.Register(RenderSystem{})
+	w.Register(UISystem{})
+
+	return w
 }
 
 func main() {
+	input := flag.String("input", "keys", "player 1 input: keys, mouse, or touch")
+	listenAddr := flag.String("listen", "", "local UDP address to bind for netplay, e.g. :9000 (requires -peer)")
+	peerAddr := flag.String("peer", "", "peer's UDP address to dial for netplay, e.g. host:9000 (enables netplay)")
+	flag.Parse()
+
 	game := &Game{}
 	// Specify the window size as you like. Here, a doubled size is specified.
-	ebiten.SetWindowSize(screenWidth*2, screenHeight*2)
+	ebiten.SetWindowSize(int(screenWidth*2), int(screenHeight*2))
 	ebiten.SetWindowTitle("Pong")
-	setup(game)
+	// Draw only clears the dirty regions markDirty records; let ebiten
+	// know not to clear the rest of the screen out from under it.
+	ebiten.SetScreenClearedEveryFrame(false)
+
+	var runner ebiten.Game = game
+	if *peerAddr != "" {
+		conn, peer, err := DialPeer(*listenAddr, *peerAddr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer conn.Close()
+
+		setup(game, *input, peer)
+		ballStart := Point{X: game.ball.pos.X, Y: game.ball.pos.Y}
+		if _, _, err := Handshake(conn, 0, ballStart); err != nil {
+			log.Fatal(err)
+		}
+		runner = NewRollbackGame(game, game.player1.Controller, peer)
+	} else {
+		setup(game, *input, nil)
+	}
+
 	// Call ebiten.RunGame to start your game loop.
-	if err := ebiten.RunGame(game); err != nil {
+	if err := ebiten.RunGame(runner); err != nil {
 		log.Fatal(err)
 	}
 }
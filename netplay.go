@@ -0,0 +1,276 @@
+package main
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"net"
+)
+
+// NetController drives a remote player over a UDP connection. It implements
+// Controller the same way ArrowKeys and FollowBall do, so Player.Update
+// doesn't need to know whether its input is local, AI, or networked.
+//
+// Peer input arrives tagged with the frame it was sampled on. Input may be
+// called for a frame before that frame's packet has arrived (the peer is
+// still on the wire, or was dropped); in that case NetController predicts
+// by repeating the last confirmed Direction. RollbackGame is responsible
+// for noticing when a later-arriving confirmation disagrees with the
+// prediction and resimulating.
+type NetController struct {
+	conn  *net.UDPConn
+	recv  chan FrameInput
+	queue map[int]Direction
+	// used records the Direction Input() actually returned for each frame,
+	// whether predicted or confirmed, so RollbackGame can tell later
+	// whether a late confirmation changes anything.
+	used  map[int]Direction
+	frame int
+	last  Direction
+}
+
+// FrameInput is a Direction tagged with the simulation frame it applies to.
+type FrameInput struct {
+	Frame int
+	Dir   Direction
+}
+
+// NewNetController starts listening for peer input on conn. conn should
+// already be connected to the peer's address (net.DialUDP), since Send
+// writes without specifying a destination.
+func NewNetController(conn *net.UDPConn) *NetController {
+	nc := &NetController{
+		conn:  conn,
+		recv:  make(chan FrameInput, 64),
+		queue: make(map[int]Direction),
+		used:  make(map[int]Direction),
+	}
+	go nc.listen()
+	return nc
+}
+
+func (nc *NetController) listen() {
+	buf := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(nc.conn, buf); err != nil {
+			return
+		}
+		nc.recv <- FrameInput{
+			Frame: int(binary.BigEndian.Uint32(buf[0:4])),
+			Dir:   Direction(binary.BigEndian.Uint32(buf[4:8])),
+		}
+	}
+}
+
+// Send broadcasts the local input for the given frame to the peer.
+func (nc *NetController) Send(frame int, dir Direction) error {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint32(buf[0:4], uint32(frame))
+	binary.BigEndian.PutUint32(buf[4:8], uint32(dir))
+	_, err := nc.conn.Write(buf)
+	return err
+}
+
+// drain moves any packets that have arrived since the last call from the
+// listener goroutine into queue, without blocking.
+func (nc *NetController) drain() {
+	for {
+		select {
+		case fi := <-nc.recv:
+			nc.queue[fi.Frame] = fi.Dir
+			nc.last = fi.Dir
+		default:
+			return
+		}
+	}
+}
+
+// Input returns the peer's input for the current frame, predicting it by
+// repeating the last confirmed Direction if it hasn't arrived yet.
+func (nc *NetController) Input() Direction {
+	nc.drain()
+	dir, ok := nc.queue[nc.frame]
+	if !ok {
+		dir = nc.last
+	}
+	nc.used[nc.frame] = dir
+	nc.frame++
+	return dir
+}
+
+// Confirmed reports the peer's confirmed input for frame, if its packet
+// has arrived.
+func (nc *NetController) Confirmed(frame int) (Direction, bool) {
+	nc.drain()
+	dir, ok := nc.queue[frame]
+	return dir, ok
+}
+
+// Used reports the Direction Input() actually returned for frame.
+func (nc *NetController) Used(frame int) (Direction, bool) {
+	dir, ok := nc.used[frame]
+	return dir, ok
+}
+
+// Rewind resets the frame counter Input() reads from, so a resimulation
+// starting at frame replays the queued/confirmed input for each tick
+// instead of continuing to hand out input for frames past the present.
+func (nc *NetController) Rewind(frame int) {
+	nc.frame = frame
+}
+
+// DialPeer resolves listenAddr and peerAddr, opens a UDP connection
+// between them, and wraps it in a NetController ready to drive player2 in
+// a netplay game. listenAddr may be "" to bind an ephemeral local port.
+// The caller is responsible for closing the returned connection.
+func DialPeer(listenAddr, peerAddr string) (*net.UDPConn, *NetController, error) {
+	var local *net.UDPAddr
+	if listenAddr != "" {
+		var err error
+		local, err = net.ResolveUDPAddr("udp", listenAddr)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	remote, err := net.ResolveUDPAddr("udp", peerAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := net.DialUDP("udp", local, remote)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, NewNetController(conn), nil
+}
+
+// Handshake exchanges a starting RNG seed and ball position with the peer
+// so both sides begin simulation from identical state. Whichever side
+// calls it first blocks until the peer calls it too.
+func Handshake(conn *net.UDPConn, localSeed int64, ballStart Point) (peerSeed int64, peerBallStart Point, err error) {
+	out := make([]byte, 24)
+	binary.BigEndian.PutUint64(out[0:8], uint64(localSeed))
+	binary.BigEndian.PutUint64(out[8:16], math.Float64bits(ballStart.X))
+	binary.BigEndian.PutUint64(out[16:24], math.Float64bits(ballStart.Y))
+	if _, err = conn.Write(out); err != nil {
+		return 0, Point{}, err
+	}
+
+	in := make([]byte, 24)
+	if _, err = io.ReadFull(conn, in); err != nil {
+		return 0, Point{}, err
+	}
+	peerSeed = int64(binary.BigEndian.Uint64(in[0:8]))
+	peerBallStart = Point{
+		X: math.Float64frombits(binary.BigEndian.Uint64(in[8:16])),
+		Y: math.Float64frombits(binary.BigEndian.Uint64(in[16:24])),
+	}
+	return peerSeed, peerBallStart, nil
+}
+
+// RollbackGame wraps a Game with GGPO-style input prediction: it keeps a
+// Snapshot per unconfirmed frame, predicts the remote player's input when
+// it hasn't arrived yet, and resimulates from the first frame where a
+// late-arriving confirmation disagrees with the prediction that was
+// actually used.
+type RollbackGame struct {
+	*Game
+	local     Controller
+	peer      *NetController
+	frame     int
+	confirmed int
+	history   map[int]Snapshot
+	predicted map[int]Direction
+	// localUsed records the Direction local.Input() actually returned for
+	// each frame, the same way NetController.used does for the peer.
+	// resimulateFrom replays these instead of letting InputSystem resample
+	// local.Input() live, since a human's keys right now aren't what was
+	// pressed on the historical frame being resimulated.
+	localUsed map[int]Direction
+}
+
+// NewRollbackGame starts a rollback session. local is the controller
+// (typically an ArrowKeys) driving player1; peer drives player2 and must
+// already be handshaken with the remote side. It locks g against further
+// rescaling, since the two peers' windows resizing differently would
+// otherwise desync their simulations (see Game.netplayLocked).
+func NewRollbackGame(g *Game, local Controller, peer *NetController) *RollbackGame {
+	g.netplayLocked = true
+	return &RollbackGame{
+		Game:      g,
+		local:     local,
+		peer:      peer,
+		history:   make(map[int]Snapshot),
+		predicted: make(map[int]Direction),
+		localUsed: make(map[int]Direction),
+	}
+}
+
+// Update samples and broadcasts local input, predicts peer input for this
+// frame, advances the simulation, then reconciles any earlier frames whose
+// predictions have since been confirmed wrong.
+func (rg *RollbackGame) Update() error {
+	rg.history[rg.frame] = rg.Snapshot()
+
+	dir := rg.local.Input()
+	rg.localUsed[rg.frame] = dir
+	if err := rg.peer.Send(rg.frame, dir); err != nil {
+		return err
+	}
+	if err := rg.Game.Update(); err != nil {
+		return err
+	}
+	// rg.Game.Update() just called rg.peer.Input() (via player2's
+	// Controller) to decide this tick's movement; record what it returned
+	// so a later confirmation can be compared against it.
+	rg.predicted[rg.frame], _ = rg.peer.Used(rg.frame)
+
+	for f := rg.confirmed; f <= rg.frame; f++ {
+		dir, ok := rg.peer.Confirmed(f)
+		if !ok {
+			break
+		}
+		if dir != rg.predicted[f] {
+			rg.resimulateFrom(f, dir)
+		}
+		rg.confirmed = f + 1
+		delete(rg.predicted, f)
+		delete(rg.history, f)
+		delete(rg.localUsed, f)
+	}
+
+	rg.frame++
+	return nil
+}
+
+// replayController feeds back a single recorded Direction instead of
+// sampling anything live, so a resimulated tick moves player1 the same way
+// it did the first time it was simulated.
+type replayController struct {
+	dir Direction
+}
+
+func (rc *replayController) Input() Direction { return rc.dir }
+
+// resimulateFrom restores the snapshot taken before from, corrects its
+// prediction, and replays every tick back up to the present frame. It
+// rewinds peer back to from first, so each replayed tick's Input() call
+// reads the queued/confirmed direction for that tick instead of whatever
+// frame peer had already reached from the original forward simulation; it
+// swaps player1's Controller to a replayController for the same reason, so
+// InputSystem replays localUsed[f] instead of resampling rg.local.Input(),
+// which for a live keyboard controller would read whatever key is pressed
+// right now rather than what was pressed on frame f.
+func (rg *RollbackGame) resimulateFrom(from int, corrected Direction) {
+	rg.Restore(rg.history[from])
+	rg.predicted[from] = corrected
+	rg.peer.Rewind(from)
+
+	localController := rg.Game.player1.Controller
+	defer func() { rg.Game.player1.Controller = localController }()
+
+	for f := from; f <= rg.frame; f++ {
+		rg.Game.player1.Controller = &replayController{dir: rg.localUsed[f]}
+		rg.history[f] = rg.Snapshot()
+		rg.Game.Update()
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+// switchableDirection is a Controller whose Input() always returns
+// whatever dir currently holds, the same way ArrowKeys always reports
+// whatever keys are pressed right now. Changing dir mid-test simulates a
+// player pressing a different key between the original simulation of a
+// frame and a later resimulation of it.
+type switchableDirection struct {
+	dir Direction
+}
+
+func (s *switchableDirection) Input() Direction { return s.dir }
+
+// loopbackUDPPair opens two UDP sockets on localhost, each dialed to the
+// other, so a write on one arrives as a read on the other - standing in
+// for two netplay peers without needing a second process.
+func loopbackUDPPair(t *testing.T) (a, b *net.UDPConn) {
+	t.Helper()
+	la, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lb, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addrA, addrB := la.LocalAddr().(*net.UDPAddr), lb.LocalAddr().(*net.UDPAddr)
+	la.Close()
+	lb.Close()
+
+	a, err = net.DialUDP("udp", addrA, addrB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err = net.DialUDP("udp", addrB, addrA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a, b
+}
+
+// TestRollbackResimulatesRecordedLocalInput checks that resimulateFrom
+// replays the local player's own recorded historical input rather than
+// resampling its Controller live. A switchableDirection stands in for the
+// keyboard and changes its answer mid-test the way a human's keys would
+// between the original simulation of a frame and a later rollback of it;
+// the resimulated result must match the original run regardless.
+func TestRollbackResimulatesRecordedLocalInput(t *testing.T) {
+	localConn, remoteConn := loopbackUDPPair(t)
+	defer localConn.Close()
+	defer remoteConn.Close()
+
+	peer := NewNetController(localConn)
+
+	game := &Game{}
+	setup(game, "keys", peer)
+	game.player1.pos.Y = 100
+
+	local := &switchableDirection{dir: Up}
+	game.player1.Controller = local
+
+	rg := NewRollbackGame(game, local, peer)
+
+	for i := 0; i < 3; i++ {
+		if err := rg.Update(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The "remote" side's real frame-0 input disagrees with what was
+	// predicted (Up, NetController's zero-value fallback), so the next
+	// Update will resimulate from frame 0.
+	packet := make([]byte, 8)
+	binary.BigEndian.PutUint32(packet[0:4], 0)
+	binary.BigEndian.PutUint32(packet[4:8], uint32(Down))
+	if _, err := remoteConn.Write(packet); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, ok := peer.Confirmed(0); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for simulated peer packet to arrive")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Simulate the human changing keys between the original simulation of
+	// frames 0-2 and the rollback that's about to replay them.
+	local.dir = Down
+
+	if err := rg.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Up, Up, Up, then Down (frame 3's own, legitimate live input):
+	// 100-2-2-2+2 = 96. If resimulateFrom resampled local.Input() live
+	// instead of replaying localUsed, frames 0-2 would see Down too,
+	// giving 100+2+2+2+2 = 108.
+	if got, want := game.player1.pos.Y, 96.0; got != want {
+		t.Fatalf("player1.pos.Y = %v, want %v (resimulation resampled live input instead of replaying recorded history)", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// followPointer turns a pointer's Y coordinate into Up/Down/Nothing by
+// comparing it to the paddle's own center, the same way FollowBall compares
+// to the ball. deadZone is kept smaller than p.Speed so the paddle doesn't
+// visibly hunt back and forth once the pointer stops moving.
+func followPointer(pointerY float64, p *Player) Direction {
+	const deadZoneFraction = 0.5
+	deadZone := p.pdl.Speed * deadZoneFraction
+	center := p.pos.Y + p.Height()/2
+	if pointerY < center-deadZone {
+		return Up
+	}
+	if pointerY > center+deadZone {
+		return Down
+	}
+	return Nothing
+}
+
+// MouseController drives a paddle by following the cursor's Y position.
+type MouseController struct {
+	Player *Player
+}
+
+func (mc *MouseController) Input() Direction {
+	_, y := ebiten.CursorPosition()
+	return followPointer(float64(y), mc.Player)
+}
+
+// Aim implements Aim. ebiten reports (0, 0) for CursorPosition when the
+// cursor is outside the window on some platforms, so that's treated as
+// "not aiming" rather than snapping to the top-left corner.
+func (mc *MouseController) Aim() (radians float64, ok bool) {
+	x, y := ebiten.CursorPosition()
+	if x == 0 && y == 0 {
+		return 0, false
+	}
+	px, py := mc.Player.Location()
+	return math.Atan2(float64(y)-py, float64(x)-px), true
+}
+
+// TouchController drives a paddle by following the first active touch's Y
+// position. With no active touch it returns Nothing, leaving the paddle
+// where it was.
+type TouchController struct {
+	Player *Player
+}
+
+func (tc *TouchController) Input() Direction {
+	ids := ebiten.TouchIDs()
+	if len(ids) == 0 {
+		return Nothing
+	}
+	_, y := ebiten.TouchPosition(ids[0])
+	return followPointer(float64(y), tc.Player)
+}
+
+func (tc *TouchController) Aim() (radians float64, ok bool) {
+	ids := ebiten.TouchIDs()
+	if len(ids) == 0 {
+		return 0, false
+	}
+	x, y := ebiten.TouchPosition(ids[0])
+	px, py := tc.Player.Location()
+	return math.Atan2(float64(y)-py, float64(x)-px), true
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// dirtyRect is a region of the screen, in logical screen coordinates, that
+// needs to be cleared and redrawn because something inside it moved.
+type dirtyRect struct {
+	X, Y, W, H float64
+}
+
+// renderState tracks what actually needs to be redrawn, and the debug
+// overlay toggle. It's only ever touched from the ebiten callback
+// goroutine (Update and Draw both run there), so it needs no locking.
+type renderState struct {
+	dirty []dirtyRect
+	debug bool
+
+	lastPlayer1, lastPlayer2, lastBall Point
+	lastScore                          [2]int
+}
+
+// markDirty records the regions player1, player2, and the ball occupied
+// before and after this tick, so Draw only has to clear those regions
+// rather than the whole screen. All three are recorded whenever any of them
+// moved or the score changed; it's a no-op only on a tick where nothing
+// moved and the score didn't change.
+func (g *Game) markDirty() {
+	r := &g.render
+	p1 := Point{X: g.player1.pos.X, Y: g.player1.pos.Y}
+	p2 := Point{X: g.player2.pos.X, Y: g.player2.pos.Y}
+	ball := Point{X: g.ball.pos.X, Y: g.ball.pos.Y}
+	unchanged := p1 == r.lastPlayer1 &&
+		p2 == r.lastPlayer2 &&
+		ball == r.lastBall &&
+		g.Score == r.lastScore
+	if unchanged {
+		return
+	}
+
+	r.dirty = append(r.dirty,
+		rectAround(r.lastPlayer1, g.player1.Width(), g.player1.Height()),
+		rectAround(p1, g.player1.Width(), g.player1.Height()),
+		rectAround(r.lastPlayer2, g.player2.Width(), g.player2.Height()),
+		rectAround(p2, g.player2.Width(), g.player2.Height()),
+		rectAround(r.lastBall, g.ball.Width(), g.ball.Height()),
+		rectAround(ball, g.ball.Width(), g.ball.Height()),
+	)
+	r.lastPlayer1, r.lastPlayer2, r.lastBall = p1, p2, ball
+	r.lastScore = g.Score
+}
+
+func rectAround(p Point, w, h float64) dirtyRect {
+	return dirtyRect{X: p.X, Y: p.Y, W: w, H: h}
+}
+
+// toggleDebugOverlay flips the on-screen TPS/FPS overlay when F1 is
+// pressed.
+func (g *Game) toggleDebugOverlay() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF1) {
+		g.render.debug = !g.render.debug
+	}
+}
+
+// drawDirty clears just the regions markDirty recorded since the last
+// Draw, rather than the whole screen. It's only effective alongside
+// ebiten.SetScreenClearedEveryFrame(false); otherwise ebiten clears
+// everything for us before Draw runs anyway.
+func (g *Game) drawDirty(screen *ebiten.Image) {
+	dirty := g.render.dirty
+	g.render.dirty = nil
+	for _, r := range dirty {
+		bounds := image.Rect(int(r.X), int(r.Y), int(r.X+r.W+1), int(r.Y+r.H+1))
+		screen.SubImage(bounds).(*ebiten.Image).Clear()
+	}
+}
+
+func (g *Game) drawDebugOverlay(screen *ebiten.Image) {
+	if !g.render.debug {
+		return
+	}
+	ebitenutil.DebugPrint(screen, fmt.Sprintf("TPS: %0.2f\nFPS: %0.2f", ebiten.ActualTPS(), ebiten.ActualFPS()))
+}
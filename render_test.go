@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+// TestMarkDirtyOnlyRecordsWhenSomethingMoved checks markDirty's core
+// contract: a no-op tick (nothing moved, score unchanged) adds nothing to
+// render.dirty, and a tick where a player moved records its old and new
+// bounds first, followed by player2's and the ball's, for drawDirty to clear.
+func TestMarkDirtyOnlyRecordsWhenSomethingMoved(t *testing.T) {
+	game := &Game{}
+	setup(game, "keys", nil)
+
+	// Settle render state to this tick's positions, the way the first
+	// real frame would, then discard what that settling recorded.
+	game.markDirty()
+	game.render.dirty = nil
+
+	game.markDirty()
+	if len(game.render.dirty) != 0 {
+		t.Fatalf("markDirty recorded %d dirty rects on an unchanged tick, want 0", len(game.render.dirty))
+	}
+
+	oldX := game.player1.pos.X
+	game.player1.pos.X += 5
+	game.markDirty()
+
+	if len(game.render.dirty) != 6 {
+		t.Fatalf("markDirty recorded %d dirty rects after player1 moved, want 6 (old+new bounds for player1, player2, and ball)", len(game.render.dirty))
+	}
+	wantOld := rectAround(Point{X: oldX, Y: game.player1.pos.Y}, game.player1.Width(), game.player1.Height())
+	if game.render.dirty[0] != wantOld {
+		t.Fatalf("dirty[0] = %+v, want player1's old bounds %+v", game.render.dirty[0], wantOld)
+	}
+	wantNew := rectAround(Point{X: game.player1.pos.X, Y: game.player1.pos.Y}, game.player1.Width(), game.player1.Height())
+	if game.render.dirty[1] != wantNew {
+		t.Fatalf("dirty[1] = %+v, want player1's new bounds %+v", game.render.dirty[1], wantNew)
+	}
+}
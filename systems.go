@@ -0,0 +1,107 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// InputSystem advances every paddle whose ControllerComp isn't AI-driven.
+// The sampling and action-state logic lives on Player (action.go); this
+// system's job is ordering, not behavior, so human and networked input
+// always resolve before AISystem's turn each tick.
+type InputSystem struct{}
+
+func (InputSystem) Update(w *World) error {
+	for _, e := range w.entities {
+		p, ok := w.players[e]
+		if !ok {
+			continue
+		}
+		ctrl, ok := w.Controllers[e]
+		if !ok || ctrl.Controller == nil {
+			continue
+		}
+		if _, ai := ctrl.Controller.(*FollowBall); ai {
+			continue
+		}
+		p.Update(w.ball)
+	}
+	return nil
+}
+
+func (InputSystem) Draw(w *World, screen *ebiten.Image) {}
+
+// AISystem advances every paddle whose ControllerComp wraps a *FollowBall.
+// It runs after InputSystem so the AI always reacts to this tick's human
+// movement rather than last tick's.
+type AISystem struct{}
+
+func (AISystem) Update(w *World) error {
+	for _, e := range w.entities {
+		p, ok := w.players[e]
+		if !ok {
+			continue
+		}
+		ctrl, ok := w.Controllers[e]
+		if !ok {
+			continue
+		}
+		if _, ai := ctrl.Controller.(*FollowBall); !ai {
+			continue
+		}
+		p.Update(w.ball)
+	}
+	return nil
+}
+
+func (AISystem) Draw(w *World, screen *ebiten.Image) {}
+
+// PhysicsSystem houses the ball/paddle collision math (Ball.Update). It
+// runs after both input systems so it sees where every paddle actually
+// ended up this tick.
+type PhysicsSystem struct{}
+
+func (PhysicsSystem) Update(w *World) error {
+	if w.ball == nil {
+		return nil
+	}
+	colliders := make([]Collider, 0, len(w.Colliders))
+	for _, e := range w.entities {
+		if c, ok := w.Colliders[e]; ok {
+			colliders = append(colliders, c)
+		}
+	}
+	w.ball.Update(colliders...)
+	return nil
+}
+
+func (PhysicsSystem) Draw(w *World, screen *ebiten.Image) {}
+
+// RenderSystem draws every entity that has both a Position and a Sprite,
+// in entity-creation order. It reads straight from the component stores
+// rather than through Player/Ball, so it draws whatever the rest of the
+// pipeline left there without needing its own view of paddle/ball state.
+type RenderSystem struct{}
+
+func (RenderSystem) Update(w *World) error { return nil }
+
+func (RenderSystem) Draw(w *World, screen *ebiten.Image) {
+	for _, e := range w.entities {
+		pos, ok := w.Positions[e]
+		if !ok {
+			continue
+		}
+		spr, ok := w.Sprites[e]
+		if !ok {
+			continue
+		}
+		options := &ebiten.DrawImageOptions{}
+		options.GeoM.Translate(pos.X, pos.Y)
+		screen.DrawImage(spr.Image, options)
+	}
+}
+
+// UISystem will host score/HUD rendering; there's nothing to draw yet, but
+// it's registered now so RenderSystem's ordering relative to it won't
+// change when that lands.
+type UISystem struct{}
+
+func (UISystem) Update(w *World) error { return nil }
+func (UISystem) Draw(w *World, screen *ebiten.Image) {}